@@ -0,0 +1,59 @@
+package ecs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+)
+
+// Artifact is the result of running the Alicloud ECS builder, pairing each
+// region it produced an image in with that image's id.
+type Artifact struct {
+	AlicloudImages map[string]string
+	BuilderIdValue string
+	Client         *ecs.Client
+}
+
+func (a *Artifact) BuilderId() string {
+	return a.BuilderIdValue
+}
+
+func (a *Artifact) Files() []string {
+	return nil
+}
+
+func (a *Artifact) Id() string {
+	regions := make([]string, 0, len(a.AlicloudImages))
+	for region := range a.AlicloudImages {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	parts := make([]string, 0, len(regions))
+	for _, region := range regions {
+		parts = append(parts, fmt.Sprintf("%s:%s", region, a.AlicloudImages[region]))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("Alicloud images were created:\n\n%s", a.Id())
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	for region, imageId := range a.AlicloudImages {
+		if err := a.Client.DeleteImage(common.Region(region), imageId); err != nil {
+			return fmt.Errorf("failed to delete image %s in region %s: %s", imageId, region, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+const BuilderId = "alicloud.ecs"
+
+type Builder struct {
+	config Config
+	runner multistep.Runner
+}
+
+func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	warnings, err := b.config.Prepare(raws...)
+	if err != nil {
+		return warnings, err
+	}
+
+	return warnings, nil
+}
+
+func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	client := ecs.NewClient(b.config.AlicloudAccessKey, b.config.AlicloudSecretKey)
+
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &b.config)
+	state.Put("client", client)
+	state.Put("hook", hook)
+	state.Put("ui", ui)
+
+	steps := b.buildSteps()
+
+	b.runner = &multistep.BasicRunner{Steps: steps}
+	b.runner.Run(ctx, state)
+
+	if rawErr, ok := state.GetOk("error"); ok {
+		return nil, rawErr.(error)
+	}
+
+	imageId, ok := state.GetOk("image_id")
+	if !ok {
+		return nil, fmt.Errorf("build was halted before an image was produced")
+	}
+
+	images := map[string]string{b.config.AlicloudRegion: imageId.(string)}
+	if copiedImageIds, ok := state.GetOk("copied_image_ids"); ok {
+		for region, copiedImageId := range copiedImageIds.(map[string]string) {
+			images[region] = copiedImageId
+		}
+	}
+
+	return &Artifact{
+		AlicloudImages: images,
+		BuilderIdValue: BuilderId,
+		Client:         client,
+	}, nil
+}
+
+// buildSteps assembles the step pipeline for the build. When image_import is
+// configured, the instance-creation/snapshot path is skipped entirely in
+// favor of importing an existing OSS disk image.
+func (b *Builder) buildSteps() []multistep.Step {
+	if !b.config.AlicloudImageImportConfig.Empty() {
+		return []multistep.Step{
+			&stepDeleteImagesAndSnapshots{},
+			&stepResolveImageVersion{},
+			&stepImportImage{},
+			&stepTagImageFamily{},
+			&stepRegionCopyImage{},
+		}
+	}
+
+	return []multistep.Step{
+		&stepDeleteImagesAndSnapshots{},
+		&stepResolveImageVersion{},
+		&stepCreateInstance{},
+		&stepCreateSnapshot{},
+		&stepCreateImage{},
+		&stepTagImageFamily{},
+		&stepRegionCopyImage{},
+	}
+}
@@ -0,0 +1,33 @@
+package ecs
+
+import (
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// Config is the configuration structure for the Alicloud ECS builder. It
+// aggregates the access, run and image configuration blocks that a user
+// supplies in their Packer template.
+type Config struct {
+	ctx interpolate.Context
+
+	AlicloudAccessKey string `mapstructure:"access_key"`
+	AlicloudSecretKey string `mapstructure:"secret_key"`
+	AlicloudRegion    string `mapstructure:"region"`
+
+	RunConfig           `mapstructure:",squash"`
+	AlicloudImageConfig `mapstructure:",squash"`
+}
+
+func (c *Config) Prepare(raws ...interface{}) ([]string, error) {
+	var errs []error
+
+	errs = append(errs, c.RunConfig.Prepare(&c.ctx, !c.AlicloudImageImportConfig.Empty())...)
+	errs = append(errs, c.AlicloudImageConfig.Prepare(&c.ctx)...)
+
+	if len(errs) > 0 {
+		return nil, packer.MultiErrorAppend(nil, errs...)
+	}
+
+	return nil, nil
+}
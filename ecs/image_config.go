@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/denverdino/aliyungo/common"
@@ -18,6 +19,8 @@ type AlicloudDiskDevice struct {
 	Description        string `mapstructure:"disk_description"`
 	DeleteWithInstance bool   `mapstructure:"disk_delete_with_instance"`
 	Device             string `mapstructure:"disk_device"`
+	Encrypted          *bool  `mapstructure:"encrypted"`
+	KMSKeyId           string `mapstructure:"kms_key_id"`
 }
 
 type AlicloudDiskDevices struct {
@@ -35,15 +38,40 @@ type AlicloudImageConfig struct {
 	AlicloudImageDestinationRegions   []string          `mapstructure:"image_copy_regions"`
 	AlicloudImageDestinationNames     []string          `mapstructure:"image_copy_names"`
 	AlicloudImageDestinationSnapshotNames     map[string][]string         `mapstructure:"image_copy_snapshot_names"`
+	AlicloudImageDestinationTags        map[string]map[string]string `mapstructure:"image_copy_tags"`
+	AlicloudImageDestinationDescriptions map[string]string            `mapstructure:"image_copy_descriptions"`
 	AlicloudImageForceDelete          bool              `mapstructure:"image_force_delete"`
 	AlicloudImageForceDeleteSnapshots bool              `mapstructure:"image_force_delete_snapshots"`
 	AlicloudImageForceDeleteInstances bool              `mapstructure:"image_force_delete_instances"`
 	AlicloudImageIgnoreDataDisks      bool              `mapstructure:"image_ignore_data_disks"`
 	AlicloudImageSkipRegionValidation bool              `mapstructure:"skip_region_validation"`
 	AlicloudImageTags                 map[string]string `mapstructure:"tags"`
+	AlicloudImageEncrypted            *bool             `mapstructure:"encrypted"`
+	AlicloudImageKMSKeyId             string            `mapstructure:"kms_key_id"`
+	AlicloudImageCopyKMSKeys          map[string]string `mapstructure:"image_copy_kms_keys"`
+	AlicloudImageFamily               string            `mapstructure:"image_family"`
+	AlicloudImageAutoVersion          bool              `mapstructure:"image_auto_version"`
+	AlicloudImageImportConfig         `mapstructure:"image_import"`
 	AlicloudDiskDevices               `mapstructure:",squash"`
 }
 
+// encryptableDiskCategories are the disk categories that Alicloud allows to
+// be created with KMS-based encryption. Local/ephemeral disks can't be
+// encrypted.
+var encryptableDiskCategories = map[string]struct{}{
+	"cloud":            {},
+	"cloud_efficiency": {},
+	"cloud_ssd":        {},
+	"cloud_essd":       {},
+}
+
+var imageFamilyRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]{1,127}$`)
+
+// imageVersionRegexp matches the "vMAJOR.MINOR.PATCH" convention used by
+// image_auto_version to discover the latest released version within a
+// family.
+var imageVersionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)$`)
+
 func (c *AlicloudImageConfig) Prepare(ctx *interpolate.Context) []error {
 	var errs []error
 	if c.AlicloudImageName == "" {
@@ -125,6 +153,126 @@ func (c *AlicloudImageConfig) Prepare(ctx *interpolate.Context) []error {
 		}
 	}
 
+	destinationRegionSet := make(map[string]struct{}, len(c.AlicloudImageDestinationRegions))
+	for _, region := range c.AlicloudImageDestinationRegions {
+		destinationRegionSet[region] = struct{}{}
+	}
+
+	if len(c.AlicloudImageDestinationTags) > 0 {
+		if len(c.AlicloudImageDestinationTags) != len(c.AlicloudImageDestinationRegions) {
+			errs = append(errs, fmt.Errorf("image_copy_tags must have exactly one entry per region in image_copy_regions"))
+		}
+
+		for region, tags := range c.AlicloudImageDestinationTags {
+			if !c.AlicloudImageSkipRegionValidation {
+				if err := validateRegion(region); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if _, ok := destinationRegionSet[region]; !ok {
+				errs = append(errs, fmt.Errorf("image_copy_tags[%s] doesn't match any region in image_copy_regions", region))
+			}
+
+			if partErrs := validateImageTags(tags, fmt.Sprintf("image_copy_tags[%s]", region)); partErrs != nil {
+				errs = append(errs, partErrs...)
+			}
+		}
+	}
+
+	if len(c.AlicloudImageDestinationDescriptions) > 0 {
+		if len(c.AlicloudImageDestinationDescriptions) != len(c.AlicloudImageDestinationRegions) {
+			errs = append(errs, fmt.Errorf("image_copy_descriptions must have exactly one entry per region in image_copy_regions"))
+		}
+
+		for region := range c.AlicloudImageDestinationDescriptions {
+			if !c.AlicloudImageSkipRegionValidation {
+				if err := validateRegion(region); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if _, ok := destinationRegionSet[region]; !ok {
+				errs = append(errs, fmt.Errorf("image_copy_descriptions[%s] doesn't match any region in image_copy_regions", region))
+			}
+		}
+	}
+
+	if c.AlicloudImageAutoVersion {
+		if c.AlicloudImageFamily == "" {
+			errs = append(errs, fmt.Errorf("image_family must be specified when image_auto_version is true"))
+		} else {
+			if partErrs := validateImageName(c.AlicloudImageFamily, "image_family"); partErrs != nil {
+				errs = append(errs, partErrs...)
+			}
+
+			if !imageFamilyRegexp.MatchString(c.AlicloudImageFamily) {
+				errs = append(errs, fmt.Errorf("image_family must match %s", imageFamilyRegexp.String()))
+			}
+		}
+	}
+
+	if c.AlicloudImageForceDelete && len(c.AlicloudImageDestinationRegions) > 0 {
+		if len(c.AlicloudImageDestinationNames) > 0 && len(c.AlicloudImageDestinationNames) != len(c.AlicloudImageDestinationRegions) {
+			errs = append(errs, fmt.Errorf("image_copy_names must have exactly one entry per region in image_copy_regions when image_force_delete is set"))
+		}
+	}
+
+	if partErrs := c.AlicloudImageImportConfig.Prepare(ctx); partErrs != nil {
+		errs = append(errs, partErrs...)
+	}
+
+	if partErrs := validateDiskEncryption(c.ECSSystemDiskMapping, "system_disk_mapping"); partErrs != nil {
+		errs = append(errs, partErrs...)
+	}
+
+	for index, disk := range c.ECSImagesDiskMappings {
+		if partErrs := validateDiskEncryption(disk, fmt.Sprintf("image_disk_mappings[%d]", index)); partErrs != nil {
+			errs = append(errs, partErrs...)
+		}
+	}
+
+	if c.AlicloudImageKMSKeyId != "" && strings.TrimSpace(c.AlicloudImageKMSKeyId) == "" {
+		errs = append(errs, fmt.Errorf("kms_key_id can't be empty or whitespace"))
+	}
+
+	if len(c.AlicloudImageCopyKMSKeys) > 0 {
+		for region, kmsKeyId := range c.AlicloudImageCopyKMSKeys {
+			if !c.AlicloudImageSkipRegionValidation {
+				if err := validateRegion(region); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if strings.TrimSpace(kmsKeyId) == "" {
+				errs = append(errs, fmt.Errorf("image_copy_kms_keys[%s] can't be empty or whitespace", region))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateDiskEncryption ensures encryption is only requested on disk
+// categories that Alicloud supports it for, and that a provided KMSKeyId
+// isn't just whitespace.
+func validateDiskEncryption(disk AlicloudDiskDevice, option string) []error {
+	var errs []error
+
+	if disk.Encrypted != nil && *disk.Encrypted {
+		if _, ok := encryptableDiskCategories[disk.DiskCategory]; !ok && disk.DiskCategory != "" {
+			errs = append(errs, fmt.Errorf("%s: disk_category %q doesn't support encryption", option, disk.DiskCategory))
+		}
+	}
+
+	if disk.KMSKeyId != "" && strings.TrimSpace(disk.KMSKeyId) == "" {
+		errs = append(errs, fmt.Errorf("%s: kms_key_id can't be empty or whitespace", option))
+	}
+
 	if len(errs) > 0 {
 		return errs
 	}
@@ -132,6 +280,83 @@ func (c *AlicloudImageConfig) Prepare(ctx *interpolate.Context) []error {
 	return nil
 }
 
+// validateImageTags enforces Alicloud's tag key/value length limits and the
+// reserved "aliyun"/"acs:" key prefixes.
+func validateImageTags(tags map[string]string, option string) []error {
+	var errs []error
+
+	for key, value := range tags {
+		if len(key) < 1 || len(key) > 64 {
+			errs = append(errs, fmt.Errorf("%s: tag key %q must be between 1 and 64 characters", option, key))
+		}
+
+		if strings.HasPrefix(key, "aliyun") || strings.HasPrefix(key, "acs:") {
+			errs = append(errs, fmt.Errorf("%s: tag key %q can't start with 'aliyun' or 'acs:'", option, key))
+		}
+
+		if len(value) > 128 {
+			errs = append(errs, fmt.Errorf("%s: tag value for key %q must be at most 128 characters", option, key))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// DestinationName returns the image name to use for the copy at the given
+// index into AlicloudImageDestinationRegions, falling back to
+// AlicloudImageName when no override was given.
+func (c *AlicloudImageConfig) DestinationName(index int) string {
+	if index < len(c.AlicloudImageDestinationNames) && c.AlicloudImageDestinationNames[index] != "" {
+		return c.AlicloudImageDestinationNames[index]
+	}
+
+	return c.AlicloudImageName
+}
+
+// bumpImagePatchVersion parses a "vMAJOR.MINOR.PATCH" (or "MAJOR.MINOR.PATCH")
+// version string and returns the same version with its patch component
+// incremented by one, preserving a leading "v" if present.
+func bumpImagePatchVersion(version string) (string, error) {
+	matches := imageVersionRegexp.FindStringSubmatch(version)
+	if matches == nil {
+		return "", fmt.Errorf("version %q doesn't match %s", version, imageVersionRegexp.String())
+	}
+
+	patch, err := strconv.Atoi(matches[3])
+	if err != nil {
+		return "", fmt.Errorf("version %q has a non-numeric patch component", version)
+	}
+
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+
+	return fmt.Sprintf("%s%s.%s.%d", prefix, matches[1], matches[2], patch+1), nil
+}
+
+// versionLess reports whether a is numerically less than b, where both are
+// "vMAJOR.MINOR.PATCH"-style strings already known to match
+// imageVersionRegexp.
+func versionLess(a, b string) bool {
+	aParts := imageVersionRegexp.FindStringSubmatch(a)
+	bParts := imageVersionRegexp.FindStringSubmatch(b)
+
+	for i := 1; i <= 3; i++ {
+		aN, _ := strconv.Atoi(aParts[i])
+		bN, _ := strconv.Atoi(bParts[i])
+		if aN != bN {
+			return aN < bN
+		}
+	}
+
+	return false
+}
+
 func validateImageName(name string, option string) []error {
 	var errs []error
 
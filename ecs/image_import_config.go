@@ -0,0 +1,67 @@
+package ecs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+var validImageImportFormats = map[string]struct{}{
+	"RAW":   {},
+	"VHD":   {},
+	"QCOW2": {},
+}
+
+// AlicloudImageImportConfig describes an existing disk image in OSS that
+// should be imported and used as the build's source image, instead of
+// launching and snapshotting a temporary instance.
+type AlicloudImageImportConfig struct {
+	AlicloudImageImportOSSBucket string `mapstructure:"oss_bucket"`
+	AlicloudImageImportOSSKey    string `mapstructure:"oss_key"`
+	AlicloudImageImportFormat   string `mapstructure:"format"`
+	AlicloudImageImportOSArch   string `mapstructure:"os_arch"`
+	AlicloudImageImportOSType   string `mapstructure:"os_type"`
+	AlicloudImageImportPlatform string `mapstructure:"platform"`
+	AlicloudImageImportRoleName string `mapstructure:"role_name"`
+}
+
+// Empty reports whether the import block was left unset in the template, in
+// which case the builder should fall back to the normal instance/snapshot
+// path.
+func (c *AlicloudImageImportConfig) Empty() bool {
+	return c.AlicloudImageImportOSSBucket == "" && c.AlicloudImageImportOSSKey == "" &&
+		c.AlicloudImageImportFormat == "" && c.AlicloudImageImportOSArch == "" &&
+		c.AlicloudImageImportOSType == "" && c.AlicloudImageImportPlatform == "" &&
+		c.AlicloudImageImportRoleName == ""
+}
+
+func (c *AlicloudImageImportConfig) Prepare(ctx *interpolate.Context) []error {
+	if c.Empty() {
+		return nil
+	}
+
+	var errs []error
+
+	if c.AlicloudImageImportOSSBucket == "" {
+		errs = append(errs, fmt.Errorf("image_import.oss_bucket must be specified"))
+	} else if strings.HasPrefix(c.AlicloudImageImportOSSBucket, "http://") || strings.HasPrefix(c.AlicloudImageImportOSSBucket, "https://") {
+		errs = append(errs, fmt.Errorf("image_import.oss_bucket can't start with 'http://' or 'https://'"))
+	}
+
+	if c.AlicloudImageImportOSSKey == "" {
+		errs = append(errs, fmt.Errorf("image_import.oss_key must be specified"))
+	} else if strings.HasPrefix(c.AlicloudImageImportOSSKey, "http://") || strings.HasPrefix(c.AlicloudImageImportOSSKey, "https://") {
+		errs = append(errs, fmt.Errorf("image_import.oss_key can't start with 'http://' or 'https://'"))
+	}
+
+	if _, ok := validImageImportFormats[c.AlicloudImageImportFormat]; !ok {
+		errs = append(errs, fmt.Errorf("image_import.format must be one of RAW, VHD, or QCOW2, got %q", c.AlicloudImageImportFormat))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package ecs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// RunConfig describes the configuration needed to launch the temporary
+// instance that Packer provisions and then images.
+type RunConfig struct {
+	AssociatePublicIpAddress bool   `mapstructure:"associate_public_ip_address"`
+	ZoneId                   string `mapstructure:"zone_id"`
+	InstanceType             string `mapstructure:"instance_type"`
+	SourceImage              string `mapstructure:"source_image"`
+	IOOptimized              bool   `mapstructure:"io_optimized"`
+
+	SSHPrivateIp bool `mapstructure:"ssh_private_ip"`
+
+	SecurityGroupId  string `mapstructure:"security_group_id"`
+	SecurityGroupName string `mapstructure:"security_group_name"`
+
+	UserData     string `mapstructure:"user_data"`
+	UserDataFile string `mapstructure:"user_data_file"`
+
+	VpcId                   string `mapstructure:"vpc_id"`
+	VpcName                 string `mapstructure:"vpc_name"`
+	VSwitchId               string `mapstructure:"vswitch_id"`
+}
+
+// Prepare validates the run configuration. When imageImportSet is true, the
+// builder is sourcing its image from OSS rather than launching and imaging a
+// temporary instance, so instance_type and source_image are not required
+// (and source_image must not be set).
+func (c *RunConfig) Prepare(ctx *interpolate.Context, imageImportSet bool) []error {
+	var errs []error
+
+	if imageImportSet {
+		if c.SourceImage != "" {
+			errs = append(errs, fmt.Errorf("source_image can't be used together with image_import"))
+		}
+	} else {
+		if c.InstanceType == "" {
+			errs = append(errs, fmt.Errorf("instance_type must be specified"))
+		}
+
+		if c.SourceImage == "" {
+			errs = append(errs, fmt.Errorf("source_image must be specified"))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
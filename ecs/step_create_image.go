@@ -0,0 +1,63 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type stepCreateImage struct {
+	imageId string
+}
+
+func (s *stepCreateImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+	instanceId := state.Get("instance_id").(string)
+
+	ui.Say(fmt.Sprintf("Creating image: %s", config.AlicloudImageName))
+
+	createImageArgs := &ecs.CreateImageArgs{
+		RegionId:     common.Region(config.AlicloudRegion),
+		InstanceId:   instanceId,
+		ImageName:    config.AlicloudImageName,
+		ImageVersion: config.AlicloudImageVersion,
+		Description:  config.AlicloudImageDescription,
+	}
+
+	if config.AlicloudImageEncrypted != nil && *config.AlicloudImageEncrypted {
+		createImageArgs.Encrypted = true
+		// An empty KMSKeyId tells Alicloud to encrypt with its default
+		// service CMK for the account.
+		createImageArgs.KMSKeyId = config.AlicloudImageKMSKeyId
+	}
+
+	imageId, err := client.CreateImage(createImageArgs)
+	if err != nil {
+		err := fmt.Errorf("Error creating image: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if err := client.WaitForImageReady(common.Region(config.AlicloudRegion), imageId, ecs.ImageStatusAvailable, 0); err != nil {
+		err := fmt.Errorf("Timeout waiting for image %s to become available: %s", imageId, err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	s.imageId = imageId
+	state.Put("image_id", imageId)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateImage) Cleanup(state multistep.StateBag) {
+	// The created image is the artifact of this build; it's left in place
+	// for the user, not torn down here.
+}
@@ -0,0 +1,102 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type stepCreateInstance struct {
+	instanceId string
+}
+
+func (s *stepCreateInstance) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Creating instance for image build")
+
+	createArgs := &ecs.CreateInstanceArgs{
+		RegionId:        common.Region(config.AlicloudRegion),
+		ImageId:         config.SourceImage,
+		InstanceType:    config.InstanceType,
+		VSwitchId:       config.VSwitchId,
+		SecurityGroupId: config.SecurityGroupId,
+		IoOptimized:     ecs.IoOptimized(config.IOOptimized),
+	}
+
+	system := config.ECSSystemDiskMapping
+	createArgs.SystemDisk = ecs.SystemDiskType{
+		Category: ecs.DiskCategory(system.DiskCategory),
+		Size:     system.DiskSize,
+		DiskName: system.DiskName,
+	}
+	setSystemDiskEncryption(&createArgs.SystemDisk, system)
+
+	for _, disk := range config.ECSImagesDiskMappings {
+		dataDisk := ecs.DataDiskType{
+			Category:           ecs.DiskCategory(disk.DiskCategory),
+			Size:               disk.DiskSize,
+			SnapshotId:         disk.SnapshotId,
+			DiskName:           disk.DiskName,
+			Description:        disk.Description,
+			Device:             disk.Device,
+			DeleteWithInstance: disk.DeleteWithInstance,
+		}
+		setDataDiskEncryption(&dataDisk, disk)
+		createArgs.DataDisk = append(createArgs.DataDisk, dataDisk)
+	}
+
+	instanceId, err := client.CreateInstance(createArgs)
+	if err != nil {
+		err := fmt.Errorf("Error creating instance: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	s.instanceId = instanceId
+	state.Put("instance_id", instanceId)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateInstance) Cleanup(state multistep.StateBag) {
+	if s.instanceId == "" {
+		return
+	}
+
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Deleting temporary instance")
+	if err := client.DeleteInstance(s.instanceId); err != nil {
+		ui.Error(fmt.Sprintf("Error deleting instance %s: %s", s.instanceId, err))
+	}
+}
+
+// setSystemDiskEncryption applies the encrypted/kms_key_id settings from the
+// system_disk_mapping config to a create-instance system disk argument.
+func setSystemDiskEncryption(out *ecs.SystemDiskType, disk AlicloudDiskDevice) {
+	if disk.Encrypted == nil || !*disk.Encrypted {
+		return
+	}
+
+	out.Encrypted = true
+	out.KMSKeyId = disk.KMSKeyId
+}
+
+// setDataDiskEncryption applies the encrypted/kms_key_id settings from a
+// data disk mapping to a create-instance data disk argument.
+func setDataDiskEncryption(out *ecs.DataDiskType, disk AlicloudDiskDevice) {
+	if disk.Encrypted == nil || !*disk.Encrypted {
+		return
+	}
+
+	out.Encrypted = true
+	out.KMSKeyId = disk.KMSKeyId
+}
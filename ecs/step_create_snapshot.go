@@ -0,0 +1,76 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type stepCreateSnapshot struct {
+	snapshotIds []string
+}
+
+func (s *stepCreateSnapshot) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+	instanceId := state.Get("instance_id").(string)
+
+	ui.Say("Creating snapshots of instance disks")
+
+	disks, _, err := client.DescribeDisks(&ecs.DescribeDisksArgs{
+		RegionId:   common.Region(config.AlicloudRegion),
+		InstanceId: instanceId,
+	})
+	if err != nil {
+		err := fmt.Errorf("Error describing disks: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	var snapshotIds []string
+	for _, disk := range disks {
+		// A snapshot of an encrypted disk is itself encrypted with the
+		// disk's KMS key automatically; there's nothing extra to pass here.
+		args := &ecs.CreateSnapshotArgs{
+			DiskId: disk.DiskId,
+		}
+
+		snapshotId, err := client.CreateSnapshot(args)
+		if err != nil {
+			err := fmt.Errorf("Error creating snapshot for disk %s: %s", disk.DiskId, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		snapshotIds = append(snapshotIds, snapshotId)
+	}
+
+	s.snapshotIds = snapshotIds
+	state.Put("snapshot_ids", snapshotIds)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateSnapshot) Cleanup(state multistep.StateBag) {
+	_, ok := state.GetOk("image_id")
+	if ok {
+		// The image was created successfully; the snapshots are now owned by
+		// the image and shouldn't be deleted out from under it.
+		return
+	}
+
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+
+	for _, snapshotId := range s.snapshotIds {
+		if err := client.DeleteSnapshot(snapshotId); err != nil {
+			ui.Error(fmt.Sprintf("Error deleting snapshot %s: %s", snapshotId, err))
+		}
+	}
+}
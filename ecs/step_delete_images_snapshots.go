@@ -0,0 +1,101 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepDeleteImagesAndSnapshots runs before image creation and, when
+// image_force_delete is set, removes any pre-existing image (and,
+// depending on image_force_delete_snapshots, its snapshots) that would
+// otherwise collide with this build - both in the source region and in
+// every region targeted by image_copy_regions, so reruns of a template
+// don't leave stale copies behind.
+type stepDeleteImagesAndSnapshots struct{}
+
+type regionImageName struct {
+	region common.Region
+	name   string
+}
+
+func (s *stepDeleteImagesAndSnapshots) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+
+	if !config.AlicloudImageForceDelete {
+		return multistep.ActionContinue
+	}
+
+	targets := []regionImageName{{common.Region(config.AlicloudRegion), config.AlicloudImageName}}
+	for index, region := range config.AlicloudImageDestinationRegions {
+		targets = append(targets, regionImageName{common.Region(region), config.DestinationName(index)})
+	}
+
+	var errs *packer.MultiError
+	for _, target := range targets {
+		if err := s.deleteImagesIn(state, target); err != nil {
+			errs = packer.MultiErrorAppend(errs, err)
+		}
+	}
+
+	if errs != nil {
+		err := fmt.Errorf("Error force-deleting existing images: %s", errs)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+// deleteImagesIn finds and removes any image owned by this account that
+// matches target.name in target.region, along with its snapshots when
+// image_force_delete_snapshots is set. Failures here are returned to the
+// caller to aggregate rather than halting other regions' cleanup.
+func (s *stepDeleteImagesAndSnapshots) deleteImagesIn(state multistep.StateBag, target regionImageName) error {
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+
+	client := ecs.NewClient(config.AlicloudAccessKey, config.AlicloudSecretKey)
+
+	images, _, err := client.DescribeImages(&ecs.DescribeImagesArgs{
+		RegionId:        target.region,
+		ImageName:       target.name,
+		ImageOwnerAlias: ecs.ImageOwnerSelf,
+	})
+	if err != nil {
+		return fmt.Errorf("region %s: error describing images: %s", target.region, err)
+	}
+
+	for _, image := range images {
+		ui.Say(fmt.Sprintf("Deleting existing image %s (%s) in region %s", image.ImageName, image.ImageId, target.region))
+
+		if err := client.DeleteImage(target.region, image.ImageId); err != nil {
+			return fmt.Errorf("region %s: error deleting image %s: %s", target.region, image.ImageId, err)
+		}
+
+		if !config.AlicloudImageForceDeleteSnapshots {
+			continue
+		}
+
+		for _, mapping := range image.DiskDeviceMappings.DiskDeviceMapping {
+			if mapping.SnapshotId == "" {
+				continue
+			}
+
+			if err := client.DeleteSnapshot(mapping.SnapshotId); err != nil {
+				return fmt.Errorf("region %s: error deleting snapshot %s: %s", target.region, mapping.SnapshotId, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *stepDeleteImagesAndSnapshots) Cleanup(state multistep.StateBag) {
+}
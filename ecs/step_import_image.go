@@ -0,0 +1,70 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepImportImage imports an existing disk image from OSS in place of
+// building one from a temporary instance. It feeds the resulting image id
+// into the same "image_id" state key that stepCreateImage produces, so the
+// family-tag and region-copy steps that follow don't need to know which path
+// produced the image.
+type stepImportImage struct {
+	imageId string
+}
+
+func (s *stepImportImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+
+	importConfig := config.AlicloudImageImportConfig
+	ui.Say(fmt.Sprintf("Importing image from oss://%s/%s", importConfig.AlicloudImageImportOSSBucket, importConfig.AlicloudImageImportOSSKey))
+
+	importArgs := &ecs.ImportImageArgs{
+		RegionId:   common.Region(config.AlicloudRegion),
+		ImageName:  config.AlicloudImageName,
+		Description: config.AlicloudImageDescription,
+		Architecture: importConfig.AlicloudImageImportOSArch,
+		OSType:     importConfig.AlicloudImageImportOSType,
+		Platform:   importConfig.AlicloudImageImportPlatform,
+		RoleName:   importConfig.AlicloudImageImportRoleName,
+		DiskDeviceMapping: []ecs.ImportImageDiskDeviceMapping{
+			{
+				Format:   importConfig.AlicloudImageImportFormat,
+				OSSBucket: importConfig.AlicloudImageImportOSSBucket,
+				OSSObject: importConfig.AlicloudImageImportOSSKey,
+			},
+		},
+	}
+
+	imageId, err := client.ImportImage(importArgs)
+	if err != nil {
+		err := fmt.Errorf("Error importing image: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if err := client.WaitForImageReady(common.Region(config.AlicloudRegion), imageId, ecs.ImageStatusAvailable, 0); err != nil {
+		err := fmt.Errorf("Timeout waiting for imported image %s to become available: %s", imageId, err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	s.imageId = imageId
+	state.Put("image_id", imageId)
+	return multistep.ActionContinue
+}
+
+func (s *stepImportImage) Cleanup(state multistep.StateBag) {
+	// The imported image is the artifact of this build; it's left in place
+	// for the user, not torn down here.
+}
@@ -0,0 +1,107 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type stepRegionCopyImage struct {
+	copiedImageIds map[string]string
+}
+
+func (s *stepRegionCopyImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+	imageId := state.Get("image_id").(string)
+
+	if len(config.AlicloudImageDestinationRegions) == 0 {
+		return multistep.ActionContinue
+	}
+
+	ui.Say(fmt.Sprintf("Copying image %s to: %s", imageId, config.AlicloudImageDestinationRegions))
+
+	s.copiedImageIds = make(map[string]string)
+	for index, destRegion := range config.AlicloudImageDestinationRegions {
+		destName := config.DestinationName(index)
+
+		destDescription := config.AlicloudImageDescription
+		if description, ok := config.AlicloudImageDestinationDescriptions[destRegion]; ok {
+			destDescription = description
+		}
+
+		copyArgs := &ecs.CopyImageArgs{
+			RegionId:            common.Region(config.AlicloudRegion),
+			ImageId:             imageId,
+			DestinationRegionId: common.Region(destRegion),
+			DestinationImageName: destName,
+			DestinationDescription: destDescription,
+		}
+
+		if kmsKeyId, ok := config.AlicloudImageCopyKMSKeys[destRegion]; ok {
+			copyArgs.Encrypted = true
+			copyArgs.DestinationImageKMSKeyId = kmsKeyId
+		} else if config.AlicloudImageEncrypted != nil && *config.AlicloudImageEncrypted {
+			copyArgs.Encrypted = true
+			copyArgs.DestinationImageKMSKeyId = config.AlicloudImageKMSKeyId
+		}
+
+		destImageId, err := client.CopyImage(copyArgs)
+		if err != nil {
+			err := fmt.Errorf("Error copying image to region %s: %s", destRegion, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if err := client.WaitForImageReady(common.Region(destRegion), destImageId, ecs.ImageStatusAvailable, 0); err != nil {
+			err := fmt.Errorf("Timeout waiting for copied image %s in region %s: %s", destImageId, destRegion, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		destTags := config.AlicloudImageTags
+		if tags, ok := config.AlicloudImageDestinationTags[destRegion]; ok {
+			destTags = tags
+		}
+
+		if len(destTags) > 0 {
+			if err := tagResources(client, common.Region(destRegion), "image", destImageId, destTags); err != nil {
+				err := fmt.Errorf("Error tagging copied image %s in region %s: %s", destImageId, destRegion, err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+		}
+
+		s.copiedImageIds[destRegion] = destImageId
+	}
+
+	state.Put("copied_image_ids", s.copiedImageIds)
+	return multistep.ActionContinue
+}
+
+func (s *stepRegionCopyImage) Cleanup(state multistep.StateBag) {
+}
+
+// tagResources applies tags to a single resource via Alicloud's
+// TagResources API, used here to tag each per-region image copy.
+func tagResources(client *ecs.Client, region common.Region, resourceType, resourceId string, tags map[string]string) error {
+	ecsTags := make([]ecs.TagResourcesTag, 0, len(tags))
+	for key, value := range tags {
+		ecsTags = append(ecsTags, ecs.TagResourcesTag{Key: key, Value: value})
+	}
+
+	return client.TagResources(&ecs.TagResourcesArgs{
+		RegionId:     region,
+		ResourceType: ecs.TagResourceType(resourceType),
+		ResourceId:   []string{resourceId},
+		Tag:          ecsTags,
+	})
+}
@@ -0,0 +1,97 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// describeImagesPageSize is the page size used when paging through
+// DescribeImages results; a short page (fewer results than this) signals
+// the last page.
+const describeImagesPageSize = 50
+
+// stepResolveImageVersion fills in AlicloudImageVersion from the highest
+// existing version already published under image_family, so templates can
+// roll images forward without hand-editing their version string.
+type stepResolveImageVersion struct{}
+
+func (s *stepResolveImageVersion) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+
+	if !config.AlicloudImageAutoVersion {
+		return multistep.ActionContinue
+	}
+
+	client := state.Get("client").(*ecs.Client)
+
+	var allImages []ecs.ImageType
+	pageNumber := 1
+	for {
+		images, _, err := client.DescribeImages(&ecs.DescribeImagesArgs{
+			RegionId:        common.Region(config.AlicloudRegion),
+			ImageOwnerAlias: ecs.ImageOwnerSelf,
+			Pagination: common.Pagination{
+				PageNumber: pageNumber,
+				PageSize:   describeImagesPageSize,
+			},
+		})
+		if err != nil {
+			err := fmt.Errorf("Error describing images for family %s: %s", config.AlicloudImageFamily, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		allImages = append(allImages, images...)
+
+		if len(images) < describeImagesPageSize {
+			break
+		}
+
+		pageNumber++
+	}
+
+	latest := "v0.0.0"
+	for _, image := range allImages {
+		if image.Tags.Tag == nil {
+			continue
+		}
+
+		inFamily := false
+		for _, tag := range image.Tags.Tag {
+			if tag.TagKey == "image_family" && tag.TagValue == config.AlicloudImageFamily {
+				inFamily = true
+				break
+			}
+		}
+		if !inFamily {
+			continue
+		}
+
+		if imageVersionRegexp.MatchString(image.ImageVersion) && versionLess(latest, image.ImageVersion) {
+			latest = image.ImageVersion
+		}
+	}
+
+	nextVersion, err := bumpImagePatchVersion(latest)
+	if err != nil {
+		err := fmt.Errorf("Error computing next version for family %s: %s", config.AlicloudImageFamily, err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Auto-versioning image family %s: %s -> %s", config.AlicloudImageFamily, latest, nextVersion))
+	config.AlicloudImageVersion = nextVersion
+
+	return multistep.ActionContinue
+}
+
+func (s *stepResolveImageVersion) Cleanup(state multistep.StateBag) {
+}
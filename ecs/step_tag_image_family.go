@@ -0,0 +1,42 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/ecs"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepTagImageFamily tags the newly built image with its image_family so
+// that later builds' stepResolveImageVersion can find it when computing the
+// next auto-bumped version.
+type stepTagImageFamily struct{}
+
+func (s *stepTagImageFamily) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	client := state.Get("client").(*ecs.Client)
+	ui := state.Get("ui").(packer.Ui)
+
+	if config.AlicloudImageFamily == "" {
+		return multistep.ActionContinue
+	}
+
+	imageId := state.Get("image_id").(string)
+
+	if err := tagResources(client, common.Region(config.AlicloudRegion), "image", imageId, map[string]string{
+		"image_family": config.AlicloudImageFamily,
+	}); err != nil {
+		err := fmt.Errorf("Error tagging image %s with family %s: %s", imageId, config.AlicloudImageFamily, err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepTagImageFamily) Cleanup(state multistep.StateBag) {
+}